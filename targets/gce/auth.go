@@ -0,0 +1,112 @@
+// Copyright 2017 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gce
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+	compute "google.golang.org/api/compute/v0.alpha"
+)
+
+// tokenSource returns the oauth2.TokenSource to use for Compute API calls,
+// based on the Instances.Auth config. With no auth config, it falls back to
+// application default credentials, same as before.
+func tokenSource(ctx context.Context, auth *Instances_Auth) (oauth2.TokenSource, error) {
+	if auth == nil {
+		return google.DefaultTokenSource(ctx, compute.ComputeScope)
+	}
+
+	scopes := auth.GetScopes()
+	if len(scopes) == 0 {
+		scopes = []string{compute.ComputeReadonlyScope}
+	}
+
+	switch {
+	case auth.GetServiceAccountJsonFile() != "":
+		b, err := ioutil.ReadFile(auth.GetServiceAccountJsonFile())
+		if err != nil {
+			return nil, fmt.Errorf("tokenSource: error reading service account json file %s: %v", auth.GetServiceAccountJsonFile(), err)
+		}
+		cfg, err := google.JWTConfigFromJSON(b, scopes...)
+		if err != nil {
+			return nil, fmt.Errorf("tokenSource: error parsing service account json file %s: %v", auth.GetServiceAccountJsonFile(), err)
+		}
+		return cfg.TokenSource(ctx), nil
+
+	case auth.GetAltTokenSource() != nil:
+		ats := auth.GetAltTokenSource()
+		if ats.GetUrl() == "" {
+			return nil, fmt.Errorf("tokenSource: alt_token_source configured without a url")
+		}
+		return newAltTokenSource(ats.GetUrl(), ats.GetBody()), nil
+
+	case auth.GetComputeTokenSourceAccount() != "":
+		return google.ComputeTokenSource(auth.GetComputeTokenSourceAccount(), scopes...), nil
+
+	default:
+		return google.DefaultTokenSource(ctx, compute.ComputeScope)
+	}
+}
+
+// altTokenResponse is the expected JSON response from an alt token source
+// URL: an access token plus its remaining lifetime in seconds.
+type altTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+// altTokenSource fetches a token by POSTing body to url. This is used by
+// clusters that mint compute-read tokens from a local agent instead of ADC.
+type altTokenSource struct {
+	url  string
+	body string
+}
+
+func newAltTokenSource(url, body string) oauth2.TokenSource {
+	return oauth2.ReuseTokenSource(nil, &altTokenSource{url: url, body: body})
+}
+
+func (a *altTokenSource) Token() (*oauth2.Token, error) {
+	resp, err := http.Post(a.url, "application/json", strings.NewReader(a.body))
+	if err != nil {
+		return nil, fmt.Errorf("altTokenSource: error fetching token from %s: %v", a.url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("altTokenSource: unexpected status %d fetching token from %s", resp.StatusCode, a.url)
+	}
+
+	var tr altTokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&tr); err != nil {
+		return nil, fmt.Errorf("altTokenSource: error decoding token response from %s: %v", a.url, err)
+	}
+	if tr.AccessToken == "" {
+		return nil, fmt.Errorf("altTokenSource: empty access_token in response from %s", a.url)
+	}
+
+	return &oauth2.Token{
+		AccessToken: tr.AccessToken,
+		Expiry:      time.Now().Add(time.Duration(tr.ExpiresIn) * time.Second),
+	}, nil
+}
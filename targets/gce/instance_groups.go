@@ -0,0 +1,307 @@
+// Copyright 2017 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gce
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/google/cloudprober/logger"
+	compute "google.golang.org/api/compute/v0.alpha"
+)
+
+// globalInstanceGroupsProvider is a singleton instance of the
+// instanceGroupsProvider struct, mirroring globalInstancesProvider.
+var (
+	globalInstanceGroupsProviderMu sync.Mutex
+	globalInstanceGroupsProvider   *instanceGroupsProvider
+)
+
+// instanceGroups represents GCE Managed Instance Group targets: the members
+// of one or more MIGs, resolved to the underlying instances.
+type instanceGroups struct {
+	pb *InstanceGroups
+}
+
+// newInstanceGroups returns a new instanceGroups object. It will initialize
+// globalInstanceGroupsProvider if needed.
+func newInstanceGroups(reEvalInterval time.Duration, igpb *InstanceGroups, l *logger.Logger) (*instanceGroups, error) {
+	if err := initGlobalInstanceGroupsProvider(reEvalInterval, igpb, l); err != nil {
+		return nil, err
+	}
+	return &instanceGroups{pb: igpb}, nil
+}
+
+// List produces a list of all instance names that are members of the
+// configured instance groups.
+func (ig *instanceGroups) List() []string {
+	return globalInstanceGroupsProvider.list()
+}
+
+// Resolve resolves a member instance's name to an IP address, using the
+// same NetworkInterface/IpType selector semantics as instances.Resolve.
+func (ig *instanceGroups) Resolve(name string, ipVer int) (net.IP, error) {
+	ins := globalInstanceGroupsProvider.get(name)
+	if ins == nil {
+		return nil, fmt.Errorf("gce.instanceGroups.resolve(%s): instance not in in-memory GCE instance-group database", name)
+	}
+	return resolveNetworkInterfaceIP(name, ins, ig.pb.GetNetworkInterface())
+}
+
+// instanceGroupsProvider is a lister which lists the members of one or more
+// Managed Instance Groups. There is supposed to be only one
+// instanceGroupsProvider per cloudprober instance: globalInstanceGroupsProvider.
+// It mirrors instancesProvider's singleton + RWMutex + periodic expand()
+// design, but resolves members against globalInstancesProvider's cache
+// instead of fetching instances itself.
+type instanceGroupsProvider struct {
+	l *logger.Logger
+
+	groups []*InstanceGroups_Group
+	// groupProjects[i] is the resolved project (explicit, or from the GCE
+	// metadata server) for groups[i].
+	groupProjects []string
+
+	// name_regex discovery: nameRe matches MIG names, and
+	// discoveryProject/discoveryZone/discoveryRegion scope the
+	// InstanceGroupManagers/RegionInstanceGroupManagers listing call used to
+	// find them. Exactly one of discoveryZone/discoveryRegion is set.
+	nameRe           *regexp.Regexp
+	discoveryProject string
+	discoveryZone    string
+	discoveryRegion  string
+
+	mu    sync.RWMutex // Mutex for names and cache
+	names []string
+	cache map[string]*compute.Instance
+}
+
+func initGlobalInstanceGroupsProvider(reEvalInterval time.Duration, igpb *InstanceGroups, l *logger.Logger) error {
+	globalInstanceGroupsProviderMu.Lock()
+	defer globalInstanceGroupsProviderMu.Unlock()
+
+	if globalInstanceGroupsProvider != nil {
+		return nil
+	}
+
+	if len(igpb.GetGroups()) == 0 && igpb.GetNameRegex() == "" {
+		return fmt.Errorf("initGlobalInstanceGroupsProvider: at least one group or a name_regex must be configured")
+	}
+
+	igp := &instanceGroupsProvider{
+		l:      l,
+		groups: igpb.GetGroups(),
+		cache:  make(map[string]*compute.Instance),
+	}
+	if igpb.GetNameRegex() != "" {
+		re, err := regexp.Compile(igpb.GetNameRegex())
+		if err != nil {
+			return fmt.Errorf("initGlobalInstanceGroupsProvider: invalid name_regex %q: %v", igpb.GetNameRegex(), err)
+		}
+		igp.nameRe = re
+
+		if (igpb.GetZone() == "") == (igpb.GetRegion() == "") {
+			return fmt.Errorf("initGlobalInstanceGroupsProvider: name_regex requires exactly one of zone or region to be set")
+		}
+		project, err := resolveProject(igpb.GetProject())
+		if err != nil {
+			return fmt.Errorf("initGlobalInstanceGroupsProvider: name_regex: %v", err)
+		}
+		if err := getOrInitInstancesProvider(project, reEvalInterval, &Instances{NetworkInterface: igpb.GetNetworkInterface()}, false, l); err != nil {
+			return fmt.Errorf("initGlobalInstanceGroupsProvider: error initializing instances cache for project %s: %v", project, err)
+		}
+		igp.discoveryProject = project
+		igp.discoveryZone = igpb.GetZone()
+		igp.discoveryRegion = igpb.GetRegion()
+	}
+
+	// We resolve group members to *compute.Instance through each group's
+	// instancesProvider cache, so make sure one is running per distinct
+	// project among the configured groups. We pass our own network_interface
+	// through so that, if we're the one initializing the provider, its
+	// hasUsableIP filtering matches the selector we'll actually resolve
+	// members with, instead of silently dropping members that only have a
+	// usable IP on a non-default NetworkInterface/IpType.
+	for _, g := range igp.groups {
+		if (g.GetZone() == "") == (g.GetRegion() == "") {
+			return fmt.Errorf("initGlobalInstanceGroupsProvider: group %s must set exactly one of zone or region", g.GetName())
+		}
+		project, err := resolveProject(g.GetProject())
+		if err != nil {
+			return fmt.Errorf("initGlobalInstanceGroupsProvider: %v", err)
+		}
+		if err := getOrInitInstancesProvider(project, reEvalInterval, &Instances{NetworkInterface: igpb.GetNetworkInterface()}, false, l); err != nil {
+			return fmt.Errorf("initGlobalInstanceGroupsProvider: error initializing instances cache for project %s: %v", project, err)
+		}
+		igp.groupProjects = append(igp.groupProjects, project)
+	}
+
+	globalInstanceGroupsProvider = igp
+
+	go func() {
+		globalInstanceGroupsProvider.expand()
+		for _ = range time.Tick(reEvalInterval) {
+			globalInstanceGroupsProvider.expand()
+		}
+	}()
+	return nil
+}
+
+// get returns the compute.Instance resource for a group member by name.
+func (igp *instanceGroupsProvider) get(name string) *compute.Instance {
+	igp.mu.RLock()
+	defer igp.mu.RUnlock()
+	return igp.cache[name]
+}
+
+func (igp *instanceGroupsProvider) list() []string {
+	igp.mu.RLock()
+	defer igp.mu.RUnlock()
+	return append([]string{}, igp.names...)
+}
+
+// listManagedInstanceNames lists the member instance names of a single
+// (possibly regional) Managed Instance Group in project.
+func listManagedInstanceNames(cs *compute.Service, project string, g *InstanceGroups_Group) ([]string, error) {
+	var names []string
+	addMember := func(instanceURL string) {
+		// instanceURL looks like
+		// ".../projects/<project>/zones/<zone>/instances/<name>".
+		i := len(instanceURL) - 1
+		for i >= 0 && instanceURL[i] != '/' {
+			i--
+		}
+		names = append(names, instanceURL[i+1:])
+	}
+
+	if g.GetRegion() != "" {
+		resp, err := cs.RegionInstanceGroupManagers.ListManagedInstances(project, g.GetRegion(), g.GetName()).Do()
+		if err != nil {
+			return nil, err
+		}
+		for _, mi := range resp.ManagedInstances {
+			addMember(mi.Instance)
+		}
+		return names, nil
+	}
+
+	resp, err := cs.InstanceGroupManagers.ListManagedInstances(project, g.GetZone(), g.GetName()).Do()
+	if err != nil {
+		return nil, err
+	}
+	for _, mi := range resp.ManagedInstances {
+		addMember(mi.Instance)
+	}
+	return names, nil
+}
+
+// discoverGroups lists the Managed Instance Groups in discoveryProject/
+// discoveryZone (or discoveryRegion) whose name matches nameRe, returning
+// one synthetic InstanceGroups_Group per match so expand can resolve their
+// members the same way it does for explicitly configured groups.
+func (igp *instanceGroupsProvider) discoverGroups(cs *compute.Service) ([]*InstanceGroups_Group, error) {
+	var names []string
+	if igp.discoveryRegion != "" {
+		resp, err := cs.RegionInstanceGroupManagers.List(igp.discoveryProject, igp.discoveryRegion).Do()
+		if err != nil {
+			return nil, err
+		}
+		for _, igm := range resp.Items {
+			names = append(names, igm.Name)
+		}
+	} else {
+		resp, err := cs.InstanceGroupManagers.List(igp.discoveryProject, igp.discoveryZone).Do()
+		if err != nil {
+			return nil, err
+		}
+		for _, igm := range resp.Items {
+			names = append(names, igm.Name)
+		}
+	}
+
+	var groups []*InstanceGroups_Group
+	for _, name := range names {
+		if !igp.nameRe.MatchString(name) {
+			continue
+		}
+		name := name
+		g := &InstanceGroups_Group{
+			Project: &igp.discoveryProject,
+			Name:    &name,
+		}
+		if igp.discoveryRegion != "" {
+			g.Region = &igp.discoveryRegion
+		} else {
+			g.Zone = &igp.discoveryZone
+		}
+		groups = append(groups, g)
+	}
+	return groups, nil
+}
+
+// expand refills the cache and names list from the configured groups (and,
+// if name_regex is set, any MIG discovered by matching it), resolving each
+// member against its project's instancesProvider cache so we don't issue a
+// separate Instances.Get call per member.
+func (igp *instanceGroupsProvider) expand() {
+	igp.l.Infof("gce.instanceGroups.expand: expanding GCE instance-group targets")
+
+	// Copy before appending discovered groups below, so we don't clobber
+	// igp.groups/igp.groupProjects if they share backing storage with a
+	// slice still referenced elsewhere.
+	groups := append([]*InstanceGroups_Group{}, igp.groups...)
+	groupProjects := append([]string{}, igp.groupProjects...)
+
+	if igp.nameRe != nil {
+		discovered, err := igp.discoverGroups(instancesProviderFor(igp.discoveryProject).cs)
+		if err != nil {
+			igp.l.Errorf("gce.instanceGroups.expand: error discovering groups matching name_regex, skipping discovery: %v", err)
+		}
+		for _, g := range discovered {
+			groups = append(groups, g)
+			groupProjects = append(groupProjects, igp.discoveryProject)
+		}
+	}
+
+	var result []string
+	cache := make(map[string]*compute.Instance)
+
+	for i, g := range groups {
+		ip := instancesProviderFor(groupProjects[i])
+		names, err := listManagedInstanceNames(ip.cs, groupProjects[i], g)
+		if err != nil {
+			igp.l.Errorf("gce.instanceGroups.expand: error listing managed instances for group %s, skipping: %v", g.GetName(), err)
+			continue
+		}
+		for _, name := range names {
+			ins := ip.get(name)
+			if ins == nil {
+				igp.l.Warningf("gce.instanceGroups.expand: member %s of group %s not found in instances cache, skipping", name, g.GetName())
+				continue
+			}
+			cache[name] = ins
+			result = append(result, name)
+		}
+	}
+
+	igp.mu.Lock()
+	defer igp.mu.Unlock()
+	igp.cache = cache
+	igp.names = result
+	igp.l.Debugf("Expanded instance-group target list: %q", result)
+}
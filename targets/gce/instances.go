@@ -15,39 +15,63 @@
 package gce
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"math/rand"
 	"net"
+	"regexp"
+	"strings"
 	"sync"
 	"time"
 
 	"cloud.google.com/go/compute/metadata"
+	"github.com/golang/protobuf/proto"
 	"github.com/google/cloudprober/logger"
+	"github.com/google/cloudprober/metrics"
 	dnsRes "github.com/google/cloudprober/targets/resolver"
 	"golang.org/x/oauth2"
-	"golang.org/x/oauth2/google"
 	"google.golang.org/api/compute/v0.alpha"
+	"google.golang.org/api/googleapi"
 )
 
-// globalInstancesProvider is a singleton instance of the instancesProvider struct.
-// It is presented as a singleton because, like forwardingRules, instances provides
-// a cache layer that is best shared by all probes.
+// defaultMaxZoneParallelism bounds how many zones we list concurrently when
+// Instances.max_zone_parallelism isn't set.
+const defaultMaxZoneParallelism = 10
+
+// zoneListTimeout bounds how long a single zone's Instances.List call is
+// allowed to take; a slow or unreachable zone shouldn't stall the whole
+// expand.
+const zoneListTimeout = 30 * time.Second
+
+// maxExpandBackoff caps how long expandLoop will wait between retries after
+// consecutive expand() failures.
+const maxExpandBackoff = 10 * time.Minute
+
+// instancesProviders holds one instancesProvider per GCE project, so a
+// single cloudprober instance can expand gce.instances targets across
+// several projects concurrently. It's presented as a set of singletons,
+// one per project, because, like forwardingRules, instances provides a
+// cache layer that is best shared by all probes targeting the same project.
 var (
-	// Mutex to safely initialize the globalInstanceProvider
-	globalInstancesProviderMu sync.Mutex
-	globalInstancesProvider   *instancesProvider
+	// Mutex to safely initialize entries in instancesProviders.
+	instancesProvidersMu sync.Mutex
+	instancesProviders   = make(map[string]*instancesProvider)
 )
 
 // instances represents GCE instances. To avoid making GCE API calls for each
 // set of GCE instances targets, for example for VM-to-VM probes over internal IP
-// and public IP, we use a global instances provider (globalInstancesProvider).
+// and public IP, we use a global instances provider, one per project.
 type instances struct {
-	pb *Instances
-	r  *dnsRes.Resolver
+	pb      *Instances
+	project string
+	r       *dnsRes.Resolver
 }
 
-// newInstances returns a new instances object. It will initialize
-// globalInstancesProvider if needed.
+// newInstances returns a new instances object. It will initialize the
+// project's instancesProvider if needed. ipb.GetProject(), if set, takes
+// precedence over project; if both are empty, the project is taken from the
+// GCE metadata server.
 func newInstances(project string, reEvalInterval time.Duration, ipb *Instances, globalResolver *dnsRes.Resolver, l *logger.Logger) (*instances, error) {
 	if ipb.GetNetworkInterface() != nil && ipb.GetUseDnsToResolve() {
 		return nil, errors.New("network_intf and use_dns_to_resolve are mutually exclusive")
@@ -55,21 +79,46 @@ func newInstances(project string, reEvalInterval time.Duration, ipb *Instances,
 	if ipb.GetUseDnsToResolve() && globalResolver == nil {
 		return nil, errors.New("use_dns_to_resolve configured, but globalResolver is nil")
 	}
-	// Initialize global instances provider if not already initialized.
-	if err := initGlobalInstancesProvider(project, reEvalInterval, l); err != nil {
+	if ipb.GetProject() != "" {
+		project = ipb.GetProject()
+	}
+	resolvedProject, err := resolveProject(project)
+	if err != nil {
+		return nil, fmt.Errorf("newInstances: %v", err)
+	}
+	// Initialize this project's instances provider if not already initialized.
+	if err := getOrInitInstancesProvider(resolvedProject, reEvalInterval, ipb, true, l); err != nil {
 		return nil, err
 	}
 	return &instances{
-		pb: ipb,
-		r:  globalResolver,
+		pb:      ipb,
+		project: resolvedProject,
+		r:       globalResolver,
 	}, nil
 }
 
+// resolveProject returns explicit if set, otherwise falls back to the GCE
+// metadata server's project, so configs running on GCE don't need to
+// hardcode a project id.
+func resolveProject(explicit string) (string, error) {
+	if explicit != "" {
+		return explicit, nil
+	}
+	if !metadata.OnGCE() {
+		return "", errors.New("no project configured and not running on GCE")
+	}
+	project, err := metadata.ProjectID()
+	if err != nil {
+		return "", fmt.Errorf("error getting project from metadata server: %v", err)
+	}
+	return project, nil
+}
+
 // List produces a list of all instances. This list is similar to running
 // "gcloud compute instances list", but with a cache layer reducing the number
 // of actual API calls made.
 func (i *instances) List() []string {
-	return globalInstancesProvider.list()
+	return instancesProviderFor(i.project).list()
 }
 
 // Resolve resolves the name into an IP address. Unless explicitly configured
@@ -79,19 +128,34 @@ func (i *instances) Resolve(name string, ipVer int) (net.IP, error) {
 	if i.pb.GetUseDnsToResolve() {
 		return i.r.Resolve(name, ipVer)
 	}
-	ins := globalInstancesProvider.get(name)
+	ins := instancesProviderFor(i.project).get(name)
 	if ins == nil {
 		return nil, fmt.Errorf("gce.instances.resolve(%s): instance not in in-memory GCE instances database", name)
 	}
+	return resolveNetworkInterfaceIP(name, ins, i.pb.GetNetworkInterface())
+}
+
+// instancesProviderFor returns the instancesProvider for project. It must
+// only be called for a project that's already been initialized through
+// getOrInitInstancesProvider.
+func instancesProviderFor(project string) *instancesProvider {
+	instancesProvidersMu.Lock()
+	defer instancesProvidersMu.Unlock()
+	return instancesProviders[project]
+}
+
+// resolveNetworkInterfaceIP picks the IP address out of a compute.Instance
+// according to the NetworkInterface selector (index + IpType), the same
+// logic used by both instances.Resolve and instanceGroups.Resolve.
+func resolveNetworkInterfaceIP(name string, ins *compute.Instance, ni *Instances_NetworkInterface) (net.IP, error) {
 	niIndex := 0
 	ipType := Instances_NetworkInterface_PRIVATE
-	ni := i.pb.GetNetworkInterface()
 	if ni != nil {
 		niIndex = int(ni.GetIndex())
 		ipType = ni.GetIpType()
 	}
 	if len(ins.NetworkInterfaces) <= niIndex {
-		return nil, fmt.Errorf("gce.instances.resolve(%s): no network interface at index: %d", name, niIndex)
+		return nil, fmt.Errorf("gce.resolve(%s): no network interface at index: %d", name, niIndex)
 	}
 	intf := ins.NetworkInterfaces[niIndex]
 	switch ipType {
@@ -99,12 +163,12 @@ func (i *instances) Resolve(name string, ipVer int) (net.IP, error) {
 		return net.ParseIP(intf.NetworkIP), nil
 	case Instances_NetworkInterface_PUBLIC:
 		if len(intf.AccessConfigs) == 0 {
-			return nil, fmt.Errorf("gce.instances.resolve(%s): no access config, instance most likely doesn't have a public IP", name)
+			return nil, fmt.Errorf("gce.resolve(%s): no access config, instance most likely doesn't have a public IP", name)
 		}
 		return net.ParseIP(intf.AccessConfigs[0].NatIP), nil
 	case Instances_NetworkInterface_ALIAS:
 		if len(intf.AliasIpRanges) == 0 {
-			return nil, fmt.Errorf("gce.instances.resolve(%s): no alias IP range", name)
+			return nil, fmt.Errorf("gce.resolve(%s): no alias IP range", name)
 		}
 		// Compute API allows specifying CIDR range as an IP address, try that first.
 		if ip := net.ParseIP(intf.AliasIpRanges[0].IpCidrRange); ip != nil {
@@ -113,30 +177,95 @@ func (i *instances) Resolve(name string, ipVer int) (net.IP, error) {
 		ip, _, err := net.ParseCIDR(intf.AliasIpRanges[0].IpCidrRange)
 		return ip, err
 	}
-	return nil, fmt.Errorf("gce.instances.resolve(%s): unknown IP type for network interface", name)
+	return nil, fmt.Errorf("gce.resolve(%s): unknown IP type for network interface", name)
 }
 
-// instancesProvider is a lister which lists GCE instances. There is supposed to
-// be only one instancesProvider object per cloudprober instance:
-// globalInstancesProvider. It implements a cache, that's populated at a regular
-// interval (configured by GlobalGCETargetsOptions.re_eval_sec
-// cloudprober/targets/targets.proto) by making GCE API calls. Listing actually
-// only returns the current contents of that cache.
+// instancesProvider is a lister which lists GCE instances. There is supposed
+// to be only one instancesProvider per project, held in instancesProviders.
+// It implements a cache, that's populated at a regular interval (configured
+// by GlobalGCETargetsOptions.re_eval_sec cloudprober/targets/targets.proto)
+// by making GCE API calls. Listing actually only returns the current
+// contents of that cache.
 type instancesProvider struct {
 	project      string
 	thisInstance string
 	l            *logger.Logger
 
+	// cs is the Compute API client, built once from the configured auth
+	// source and reused across expand() cycles instead of dialing a fresh
+	// HTTP client every time.
+	cs *compute.Service
+
+	// Zone/region filters, taken from the Instances config of whichever
+	// getOrInitInstancesProvider() call first initializes the provider.
+	zones              map[string]bool
+	zonesRe            *regexp.Regexp
+	regions            map[string]bool
+	regionsRe          *regexp.Regexp
+	maxZoneParallelism int
+
+	// Server-side filter=, passed straight through to Instances.List, built
+	// from Instances.filter plus the labels/status/exclude_preemptible
+	// shortcuts.
+	listFilter string
+
+	// networkInterface is used to skip, during expand, instances that have
+	// no usable IP for the configured NetworkInterface/IpType.
+	networkInterface *Instances_NetworkInterface
+
+	// pb is the Instances config that initialized this provider, kept
+	// around so a later getOrInitInstancesProvider call for the same
+	// project can warn if it's configured differently: instancesProvider is
+	// a singleton per project, so only the first config for a project
+	// actually takes effect.
+	pb *Instances
+
+	// lastZonesETag/lastZonesList cache the last successfully fetched zones
+	// list; if the zones API returns the same ETag, we reuse lastZonesList
+	// instead of re-fetching it, since zones rarely change. Instances
+	// within those zones still get re-listed every cycle.
+	lastZonesETag string
+	lastZonesList *compute.ZoneList
+
 	mu    sync.RWMutex // Mutex for names and cache
 	names []string
 	cache map[string]*compute.Instance
+
+	// statsMu guards the expand() observability fields below, surfaced
+	// through Metrics() so operators can alert on a stale cache.
+	statsMu               sync.Mutex
+	lastSuccessfulExpand  time.Time
+	lastError             error
+	expandDurationSeconds float64
+	instanceCount         int
+	consecutiveFailures   int
+	lastZoneFailures      int
 }
 
-func initGlobalInstancesProvider(project string, reEvalInterval time.Duration, l *logger.Logger) error {
-	globalInstancesProviderMu.Lock()
-	defer globalInstancesProviderMu.Unlock()
+// getOrInitInstancesProvider returns the instancesProvider for project,
+// initializing it (and starting its expand() goroutine) on first use.
+// authoritative should be true for callers whose Instances config is meant
+// to fully describe how project should be listed (i.e. gce.instances
+// itself), and false for callers that merely want to reuse project's
+// existing cache (i.e. gce.instanceGroups, which resolves MIG members
+// against it instead of listing them directly): a non-authoritative ipb
+// never trips the config-mismatch warning below, since it's expected to
+// legitimately differ from (or be a subset of) whatever gce.instances
+// config is actually driving the cache.
+func getOrInitInstancesProvider(project string, reEvalInterval time.Duration, ipb *Instances, authoritative bool, l *logger.Logger) error {
+	instancesProvidersMu.Lock()
+	defer instancesProvidersMu.Unlock()
 
-	if globalInstancesProvider != nil {
+	if existing := instancesProviders[project]; existing != nil {
+		// instancesProvider is a singleton per project: whichever config got
+		// here first wins and every subsequent config for the same project
+		// is silently ignored. That's fine when configs agree, but a
+		// mismatch usually means two probes/targets disagree about how
+		// project should be listed, so warn loudly instead of silently
+		// applying the first one everywhere.
+		if authoritative && !proto.Equal(existing.pb, ipb) {
+			l.Warningf("getOrInitInstancesProvider(%s): instancesProvider already initialized with a different Instances config; ignoring this one and continuing to use the first: first=%v, ignored=%v", project, existing.pb, ipb)
+		}
 		return nil
 	}
 
@@ -145,25 +274,136 @@ func initGlobalInstancesProvider(project string, reEvalInterval time.Duration, l
 		var err error
 		thisInstance, err = metadata.InstanceName()
 		if err != nil {
-			return fmt.Errorf("initGlobalInstancesProvider: error while getting current instance name: %v", err)
+			return fmt.Errorf("getOrInitInstancesProvider(%s): error while getting current instance name: %v", project, err)
+		}
+		l.Infof("getOrInitInstancesProvider(%s): this instance: %s", project, thisInstance)
+	}
+
+	ip := &instancesProvider{
+		project:            project,
+		thisInstance:       thisInstance,
+		cache:              make(map[string]*compute.Instance),
+		l:                  l,
+		maxZoneParallelism: defaultMaxZoneParallelism,
+		pb:                 ipb,
+	}
+	if n := int(ipb.GetMaxZoneParallelism()); n > 0 {
+		ip.maxZoneParallelism = n
+	}
+	if len(ipb.GetZones()) > 0 {
+		ip.zones = make(map[string]bool)
+		for _, z := range ipb.GetZones() {
+			ip.zones[z] = true
+		}
+	}
+	if ipb.GetZonesRegex() != "" {
+		re, err := regexp.Compile(ipb.GetZonesRegex())
+		if err != nil {
+			return fmt.Errorf("getOrInitInstancesProvider: invalid zones_regex %q: %v", ipb.GetZonesRegex(), err)
 		}
-		l.Infof("initGlobalInstancesProvider: this instance: %s", thisInstance)
+		ip.zonesRe = re
 	}
-	globalInstancesProvider = &instancesProvider{
-		project:      project,
-		thisInstance: thisInstance,
-		cache:        make(map[string]*compute.Instance),
-		l:            l,
+	if len(ipb.GetRegions()) > 0 {
+		ip.regions = make(map[string]bool)
+		for _, r := range ipb.GetRegions() {
+			ip.regions[r] = true
+		}
 	}
-	go func() {
-		globalInstancesProvider.expand()
-		for _ = range time.Tick(reEvalInterval) {
-			globalInstancesProvider.expand()
+	if ipb.GetRegionsRegex() != "" {
+		re, err := regexp.Compile(ipb.GetRegionsRegex())
+		if err != nil {
+			return fmt.Errorf("getOrInitInstancesProvider: invalid regions_regex %q: %v", ipb.GetRegionsRegex(), err)
 		}
-	}()
+		ip.regionsRe = re
+	}
+	ip.listFilter = buildListFilter(ipb)
+	ip.networkInterface = ipb.GetNetworkInterface()
+
+	ts, err := tokenSource(context.Background(), ipb.GetAuth())
+	if err != nil {
+		return fmt.Errorf("getOrInitInstancesProvider: error building token source: %v", err)
+	}
+	cs, err := compute.New(oauth2.NewClient(context.Background(), ts))
+	if err != nil {
+		return fmt.Errorf("getOrInitInstancesProvider: error building compute client: %v", err)
+	}
+	ip.cs = cs
+
+	instancesProviders[project] = ip
+
+	go ip.expandLoop(reEvalInterval)
 	return nil
 }
 
+// zoneMatches returns true if the given zone (and the region it belongs to,
+// derived from its name, e.g. "us-central1-a" -> "us-central1") passes the
+// configured zones/zones_regex/regions/regions_regex filters. With no
+// filters configured, every zone matches.
+func (ip *instancesProvider) zoneMatches(zoneName string) bool {
+	if ip.zones == nil && ip.zonesRe == nil && ip.regions == nil && ip.regionsRe == nil {
+		return true
+	}
+	if ip.zones != nil && ip.zones[zoneName] {
+		return true
+	}
+	if ip.zonesRe != nil && ip.zonesRe.MatchString(zoneName) {
+		return true
+	}
+	if region := zoneToRegion(zoneName); region != "" {
+		if ip.regions != nil && ip.regions[region] {
+			return true
+		}
+		if ip.regionsRe != nil && ip.regionsRe.MatchString(region) {
+			return true
+		}
+	}
+	return false
+}
+
+// zoneToRegion derives a region name from a zone name, e.g.
+// "us-central1-a" -> "us-central1".
+func zoneToRegion(zoneName string) string {
+	i := strings.LastIndex(zoneName, "-")
+	if i <= 0 {
+		return ""
+	}
+	return zoneName[:i]
+}
+
+// buildListFilter combines Instances.filter with the labels/status/
+// exclude_preemptible shortcuts into a single filter= expression suitable
+// for cs.Instances.List(project, zone).Filter(f).
+func buildListFilter(ipb *Instances) string {
+	var clauses []string
+	if f := ipb.GetFilter(); f != "" {
+		// Parenthesize so a top-level OR in the user-supplied filter isn't
+		// silently re-scoped by operator precedence once ANDed below.
+		clauses = append(clauses, "("+f+")")
+	}
+	for _, lf := range ipb.GetLabels() {
+		clauses = append(clauses, fmt.Sprintf("labels.%s=%s", lf.GetKey(), lf.GetValue()))
+	}
+	if statuses := ipb.GetStatus(); len(statuses) > 0 {
+		var statusClauses []string
+		for _, s := range statuses {
+			statusClauses = append(statusClauses, fmt.Sprintf("status=%s", s.String()))
+		}
+		clauses = append(clauses, "("+strings.Join(statusClauses, " OR ")+")")
+	}
+	if ipb.GetExcludePreemptible() {
+		clauses = append(clauses, "scheduling.preemptible=false")
+	}
+	return strings.Join(clauses, " AND ")
+}
+
+// hasUsableIP reports whether ins has a usable IP for the configured
+// NetworkInterface/IpType selector, so expand() can drop instances that
+// List() would otherwise return but Resolve() could never resolve.
+func hasUsableIP(ins *compute.Instance, ni *Instances_NetworkInterface) bool {
+	ip, err := resolveNetworkInterfaceIP(ins.Name, ins, ni)
+	return err == nil && ip != nil
+}
+
 // get returns compute.Instance resource from the cache by name.
 func (ip *instancesProvider) get(name string) *compute.Instance {
 	ip.mu.RLock()
@@ -177,54 +417,231 @@ func (ip *instancesProvider) list() []string {
 	return append([]string{}, ip.names...)
 }
 
-// listInstances runs equivalent API calls as "gcloud compute instances list",
-// and is what is used to populate the cache.
-func listInstances(project string) ([]*compute.Instance, error) {
-	client, err := google.DefaultClient(oauth2.NoContext, compute.ComputeScope)
-	if err != nil {
-		return nil, err
+// listInstancesInZone runs the equivalent of "gcloud compute instances list
+// --zones=<zone>" for a single zone, applying filter (if non-empty) and
+// paging through all results instead of taking only the first page.
+func listInstancesInZone(cs *compute.Service, project, zone, filter string) ([]*compute.Instance, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), zoneListTimeout)
+	defer cancel()
+
+	var result []*compute.Instance
+	call := cs.Instances.List(project, zone).Context(ctx)
+	if filter != "" {
+		call = call.Filter(filter)
 	}
-	cs, err := compute.New(client)
-	if err != nil {
-		return nil, err
+	pageToken := ""
+	for {
+		if pageToken != "" {
+			call = call.PageToken(pageToken)
+		}
+		instanceList, err := call.Do()
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, instanceList.Items...)
+		if instanceList.NextPageToken == "" {
+			break
+		}
+		pageToken = instanceList.NextPageToken
+	}
+	return result, nil
+}
+
+// listZones fetches the project's zones list with a conditional request,
+// reusing lastZonesList when the server answers 304 Not Modified for the last
+// successfully fetched ETag: zones churn far less often
+// than the instances inside them, but we still want to re-list those
+// instances every cycle, so callers must not skip the rest of expand() just
+// because this returned a cached list.
+func (ip *instancesProvider) listZones() (*compute.ZoneList, error) {
+	call := ip.cs.Zones.List(ip.project)
+	if ip.lastZonesETag != "" {
+		call.Header().Set("If-None-Match", ip.lastZonesETag)
 	}
-	zonesList, err := cs.Zones.List(project).Do()
+	zonesList, err := call.Do()
 	if err != nil {
+		if googleapi.IsNotModified(err) {
+			return ip.lastZonesList, nil
+		}
 		return nil, err
 	}
-	var result []*compute.Instance
-	var instanceList *compute.InstanceList
+	if etag := zonesList.Header.Get("Etag"); etag != "" {
+		ip.lastZonesETag = etag
+	}
+	ip.lastZonesList = zonesList
+	return zonesList, nil
+}
+
+// listInstances runs equivalent API calls as "gcloud compute instances list",
+// fanning out per-zone Instances.List calls concurrently (bounded by
+// ip.maxZoneParallelism), and is what is used to populate the cache. A zone whose
+// listing fails is logged and skipped rather than aborting the whole expand,
+// unless every attempted zone failed, in which case it returns an error so
+// callers don't mistake "nothing came back because everything errored" for
+// an empty-but-healthy project.
+func (ip *instancesProvider) listInstances(zonesList *compute.ZoneList) ([]*compute.Instance, int, error) {
+	var zones []string
 	for _, zone := range zonesList.Items {
-		instanceList, err = cs.Instances.List(project, zone.Name).Do()
-		if err != nil {
-			return nil, err
+		if ip.zoneMatches(zone.Name) {
+			zones = append(zones, zone.Name)
 		}
-		result = append(result, instanceList.Items...)
 	}
-	return result, nil
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		result   []*compute.Instance
+		failures int
+		sem      = make(chan struct{}, ip.maxZoneParallelism)
+	)
+	for _, zone := range zones {
+		zone := zone
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			instances, err := listInstancesInZone(ip.cs, ip.project, zone, ip.listFilter)
+			if err != nil {
+				ip.l.Errorf("gce.instances.expand: error while listing instances in zone %s, skipping: %v", zone, err)
+				mu.Lock()
+				failures++
+				mu.Unlock()
+				return
+			}
+			mu.Lock()
+			result = append(result, instances...)
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	if len(zones) > 0 && failures == len(zones) {
+		return nil, failures, fmt.Errorf("gce.instances.expand: all %d zone(s) failed to list instances", failures)
+	}
+	return result, failures, nil
 }
 
-// expand will refill the cache, and update names.
-func (ip *instancesProvider) expand() {
+// expand rebuilds the cache and names list from scratch each cycle, so
+// deleted instances disappear instead of lingering in a cache that's only
+// ever added to. It records expand stats (exposed via Metrics()) and
+// returns an error so expandLoop can back off on consecutive failures.
+func (ip *instancesProvider) expand() error {
 	ip.l.Infof("gce.instances.expand: expanding GCE targets")
+	start := time.Now()
 
-	computeInstances, err := listInstances(ip.project)
+	zonesList, err := ip.listZones()
 	if err != nil {
-		ip.l.Errorf("gce.instances.expand: error while getting list of all instances: %v", err)
-		return
+		ip.recordExpandResult(start, 0, 0, err)
+		return err
+	}
+
+	computeInstances, zoneFailures, err := ip.listInstances(zonesList)
+	if err != nil {
+		ip.recordExpandResult(start, 0, zoneFailures, err)
+		return err
 	}
 
 	var result []string
-	ip.mu.Lock()
-	defer ip.mu.Unlock()
+	cache := make(map[string]*compute.Instance)
 	for _, ins := range computeInstances {
 		if ins.Name == ip.thisInstance {
 			continue
 		}
-		ip.cache[ins.Name] = ins
+		if !hasUsableIP(ins, ip.networkInterface) {
+			ip.l.Debugf("gce.instances.expand: skipping %s, no usable IP for configured network_interface", ins.Name)
+			continue
+		}
+		cache[ins.Name] = ins
 		result = append(result, ins.Name)
 	}
 
-	ip.l.Debugf("Expanded target list: %q", result)
+	ip.mu.Lock()
+	ip.cache = cache
 	ip.names = result
+	ip.mu.Unlock()
+
+	ip.l.Debugf("Expanded target list: %q", result)
+	ip.recordExpandResult(start, len(result), zoneFailures, nil)
+	return nil
+}
+
+// recordExpandResult updates the observability fields surfaced through
+// Metrics() and resets/increments the consecutive-failure counter that
+// expandLoop uses to back off. zoneFailures is the number of zones that
+// failed to list in this cycle, even on an otherwise-successful expand, so
+// a partial outage stays visible in Metrics() instead of reading as fully
+// healthy.
+func (ip *instancesProvider) recordExpandResult(start time.Time, instanceCount, zoneFailures int, err error) {
+	ip.statsMu.Lock()
+	defer ip.statsMu.Unlock()
+
+	ip.expandDurationSeconds = time.Since(start).Seconds()
+	ip.lastError = err
+	ip.lastZoneFailures = zoneFailures
+	if err != nil {
+		ip.consecutiveFailures++
+		return
+	}
+	ip.consecutiveFailures = 0
+	ip.lastSuccessfulExpand = start
+	ip.instanceCount = instanceCount
+}
+
+// Metrics returns the current expand() observability fields as a
+// cloudprober EventMetrics, for surfacers to export so operators can alert
+// on a stale cache.
+func (ip *instancesProvider) Metrics() *metrics.EventMetrics {
+	ip.statsMu.Lock()
+	defer ip.statsMu.Unlock()
+
+	em := metrics.NewEventMetrics(time.Now()).
+		AddLabel("project", ip.project).
+		AddMetric("gce_instances_count", metrics.NewInt(int64(ip.instanceCount))).
+		AddMetric("gce_instances_expand_duration_seconds", metrics.NewFloat(ip.expandDurationSeconds)).
+		AddMetric("gce_instances_consecutive_failures", metrics.NewInt(int64(ip.consecutiveFailures))).
+		AddMetric("gce_instances_zone_failures", metrics.NewInt(int64(ip.lastZoneFailures)))
+	if !ip.lastSuccessfulExpand.IsZero() {
+		em.AddMetric("gce_instances_seconds_since_success", metrics.NewFloat(time.Since(ip.lastSuccessfulExpand).Seconds()))
+	}
+	return em
+}
+
+// expandLoop runs expand() every reEvalInterval, backing off exponentially
+// (with jitter) on consecutive failures instead of hammering the API at a
+// fixed interval, and serving the last good cache in the meantime.
+func (ip *instancesProvider) expandLoop(reEvalInterval time.Duration) {
+	for {
+		var wait time.Duration
+		if err := ip.expand(); err != nil {
+			ip.l.Errorf("gce.instances.expand: error while getting list of all instances: %v", err)
+			wait = backoffWithJitter(reEvalInterval, ip.failureCount())
+		} else {
+			wait = reEvalInterval
+		}
+		time.Sleep(wait)
+	}
+}
+
+// failureCount returns the current consecutive-failure count.
+func (ip *instancesProvider) failureCount() int {
+	ip.statsMu.Lock()
+	defer ip.statsMu.Unlock()
+	return ip.consecutiveFailures
+}
+
+// backoffWithJitter returns base*2^failures (capped at maxExpandBackoff)
+// plus up to 50% random jitter, so a flaky API doesn't get hammered by
+// every provider backing off in lockstep.
+func backoffWithJitter(base time.Duration, failures int) time.Duration {
+	backoff := base
+	for i := 0; i < failures && backoff < maxExpandBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxExpandBackoff {
+		backoff = maxExpandBackoff
+	}
+	jitter := time.Duration(rand.Int63n(int64(backoff)/2 + 1))
+	return backoff + jitter
 }
\ No newline at end of file
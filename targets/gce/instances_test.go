@@ -0,0 +1,175 @@
+// Copyright 2017 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gce
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+)
+
+func TestZoneToRegion(t *testing.T) {
+	tests := []struct {
+		zone string
+		want string
+	}{
+		{"us-central1-a", "us-central1"},
+		{"asia-east1-b", "asia-east1"},
+		{"global", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := zoneToRegion(tt.zone); got != tt.want {
+			t.Errorf("zoneToRegion(%q) = %q, want %q", tt.zone, got, tt.want)
+		}
+	}
+}
+
+func TestZoneMatches(t *testing.T) {
+	tests := []struct {
+		name string
+		ip   *instancesProvider
+		zone string
+		want bool
+	}{
+		{"no filters matches everything", &instancesProvider{}, "us-central1-a", true},
+		{
+			"zones allowlist hit",
+			&instancesProvider{zones: map[string]bool{"us-central1-a": true}},
+			"us-central1-a",
+			true,
+		},
+		{
+			"zones allowlist miss",
+			&instancesProvider{zones: map[string]bool{"us-central1-a": true}},
+			"us-central1-b",
+			false,
+		},
+		{
+			"zones_regex hit",
+			&instancesProvider{zonesRe: regexp.MustCompile("us-central1-.*")},
+			"us-central1-a",
+			true,
+		},
+		{
+			"regions allowlist hit via derived region",
+			&instancesProvider{regions: map[string]bool{"us-central1": true}},
+			"us-central1-a",
+			true,
+		},
+		{
+			"regions_regex hit via derived region",
+			&instancesProvider{regionsRe: regexp.MustCompile("us-.*")},
+			"us-central1-a",
+			true,
+		},
+		{
+			"regions filter configured but zone's region doesn't match",
+			&instancesProvider{regions: map[string]bool{"europe-west1": true}},
+			"us-central1-a",
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.ip.zoneMatches(tt.zone); got != tt.want {
+				t.Errorf("zoneMatches(%q) = %v, want %v", tt.zone, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildListFilter(t *testing.T) {
+	tests := []struct {
+		name string
+		ipb  *Instances
+		want string
+	}{
+		{"empty config", &Instances{}, ""},
+		{
+			"raw filter is parenthesized",
+			&Instances{Filter: proto.String("labels.env=prod OR labels.env=staging")},
+			"(labels.env=prod OR labels.env=staging)",
+		},
+		{
+			"labels ANDed in",
+			&Instances{Labels: []*Instances_LabelFilter{
+				{Key: proto.String("env"), Value: proto.String("prod")},
+			}},
+			"labels.env=prod",
+		},
+		{
+			"statuses ORed together in parentheses",
+			&Instances{Status: []InstanceStatus{InstanceStatus_RUNNING, InstanceStatus_STAGING}},
+			"(status=RUNNING OR status=STAGING)",
+		},
+		{
+			"exclude_preemptible",
+			&Instances{ExcludePreemptible: proto.Bool(true)},
+			"scheduling.preemptible=false",
+		},
+		{
+			"all clauses ANDed together in order",
+			&Instances{
+				Filter:             proto.String("a=b OR c=d"),
+				Labels:             []*Instances_LabelFilter{{Key: proto.String("env"), Value: proto.String("prod")}},
+				Status:             []InstanceStatus{InstanceStatus_RUNNING},
+				ExcludePreemptible: proto.Bool(true),
+			},
+			"(a=b OR c=d) AND labels.env=prod AND (status=RUNNING) AND scheduling.preemptible=false",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := buildListFilter(tt.ipb); got != tt.want {
+				t.Errorf("buildListFilter() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBackoffWithJitter(t *testing.T) {
+	base := time.Second
+
+	if got := backoffWithJitter(base, 0); got < base || got > base+base/2 {
+		t.Errorf("backoffWithJitter(%v, 0) = %v, want in [%v, %v]", base, got, base, base+base/2)
+	}
+
+	// With enough consecutive failures, the doubling must have saturated at
+	// maxExpandBackoff, plus up to 50% jitter.
+	got := backoffWithJitter(base, 100)
+	if got < maxExpandBackoff || got > maxExpandBackoff+maxExpandBackoff/2 {
+		t.Errorf("backoffWithJitter(%v, 100) = %v, want in [%v, %v]", base, got, maxExpandBackoff, maxExpandBackoff+maxExpandBackoff/2)
+	}
+}
+
+func TestResolveProject(t *testing.T) {
+	got, err := resolveProject("my-project")
+	if err != nil {
+		t.Fatalf("resolveProject(explicit) returned error: %v", err)
+	}
+	if got != "my-project" {
+		t.Errorf("resolveProject(explicit) = %q, want %q", got, "my-project")
+	}
+
+	// With no explicit project and (almost certainly) not running on GCE in
+	// a test environment, it should fall back to an error rather than an
+	// empty project.
+	if _, err := resolveProject(""); err == nil {
+		t.Errorf("resolveProject(\"\") off-GCE: got nil error, want one")
+	}
+}